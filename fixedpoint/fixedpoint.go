@@ -0,0 +1,132 @@
+// Package fixedpoint provides a fixed-point decimal type for money math that
+// needs to survive thousands of fills without float64 drift.
+package fixedpoint
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// scale is the number of fractional decimal digits kept internally. 1e8
+// matches typical crypto exchange price/quantity precision.
+const scale = 1e8
+
+// Zero is the additive identity, useful as a starting accumulator.
+var Zero Value
+
+// Value is a fixed-point decimal stored as an int64 scaled by 1e8. All
+// arithmetic is exact integer math, so it does not accumulate the rounding
+// drift float64 does over many fills.
+type Value int64
+
+// NewFromFloat converts a float64 into a Value, rounding to the nearest
+// scaled unit. This is the only place precision is allowed to be lost when
+// data enters the decimal domain.
+func NewFromFloat(f float64) Value {
+	return Value(math.Round(f * scale))
+}
+
+// NewFromInt converts a whole number into a Value.
+func NewFromInt(i int64) Value {
+	return Value(i * scale)
+}
+
+// Float64 converts back to a float64. This is the conversion-centralized
+// helper for API egress: callers should only call it at the boundary of the
+// public, float64-typed Trader interface, not in the middle of internal math.
+func (v Value) Float64() float64 {
+	return float64(v) / scale
+}
+
+// String formats v using its natural decimal precision.
+func (v Value) String() string {
+	return strconv.FormatFloat(v.Float64(), 'f', -1, 64)
+}
+
+func (v Value) Add(o Value) Value { return v + o }
+func (v Value) Sub(o Value) Value { return v - o }
+func (v Value) Neg() Value        { return -v }
+
+// Mul multiplies two scaled values, correcting for the double scale factor
+// introduced by multiplying two fixed-point numbers together. The
+// intermediate product is computed in big.Int, not float64: at realistic
+// price*quantity scale (both already scaled by 1e8) that product routinely
+// exceeds float64's 2^53 exact-integer range, which would silently
+// reintroduce the drift this type exists to avoid.
+func (v Value) Mul(o Value) Value {
+	product := new(big.Int).Mul(big.NewInt(int64(v)), big.NewInt(int64(o)))
+	return Value(divRoundBig(product, big.NewInt(scale)).Int64())
+}
+
+// Div divides v by o, returning Zero if o is zero. Like Mul, the
+// intermediate numerator is computed in big.Int to avoid float64 rounding.
+func (v Value) Div(o Value) Value {
+	if o == 0 {
+		return Zero
+	}
+	numerator := new(big.Int).Mul(big.NewInt(int64(v)), big.NewInt(scale))
+	return Value(divRoundBig(numerator, big.NewInt(int64(o))).Int64())
+}
+
+// divRoundBig divides num by den and rounds the result to the nearest
+// integer, half away from zero (matching math.Round's tie-breaking).
+func divRoundBig(num, den *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	doubledRem := new(big.Int).Abs(rem)
+	doubledRem.Lsh(doubledRem, 1)
+	if doubledRem.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) == (den.Sign() < 0) {
+			quo.Add(quo, big.NewInt(1))
+		} else {
+			quo.Sub(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+// MulInt multiplies v by a plain integer, e.g. a leverage factor.
+func (v Value) MulInt(i int64) Value { return v * Value(i) }
+
+// DivInt divides v by a plain integer, e.g. a leverage factor.
+func (v Value) DivInt(i int64) Value {
+	if i == 0 {
+		return Zero
+	}
+	return Value(int64(v) / i)
+}
+
+func (v Value) Compare(o Value) int {
+	switch {
+	case v < o:
+		return -1
+	case v > o:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (v Value) IsZero() bool     { return v == 0 }
+func (v Value) IsPositive() bool { return v > 0 }
+func (v Value) IsNegative() bool { return v < 0 }
+
+// Max returns the larger of a and b.
+func Max(a, b Value) Value {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Min returns the smaller of a and b.
+func Min(a, b Value) Value {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+package fixedpoint
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// exactMul computes a*b/scale independently in big.Int, rounding half away
+// from zero, as the ground truth against which Value.Mul is checked.
+func exactMul(a, b int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	quo, rem := new(big.Int).QuoRem(product, big.NewInt(scale), new(big.Int))
+	doubled := new(big.Int).Abs(rem)
+	doubled.Lsh(doubled, 1)
+	if doubled.Cmp(big.NewInt(scale)) >= 0 {
+		if product.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo.Int64()
+}
+
+func TestMulExactAtRealisticScale(t *testing.T) {
+	price := NewFromFloat(97531.87654321)
+	qty := NewFromFloat(3.14159265)
+
+	// At this scale the raw product is far past float64's 2^53 exact-integer
+	// range, so this fails if Mul ever routes through a float64 intermediate.
+	got := price.Mul(qty)
+	want := exactMul(int64(price), int64(qty))
+	if int64(got) != want {
+		t.Errorf("Mul = %d, want %d", int64(got), want)
+	}
+}
+
+func TestMulNoDriftOverManyFills(t *testing.T) {
+	price := NewFromFloat(97531.87654321)
+	qty := NewFromFloat(3.14159265)
+
+	total := Zero
+	for i := 0; i < 10000; i++ {
+		total = total.Add(price.Mul(qty))
+	}
+
+	// Fixed-point Add never rounds, so 10,000 identical fills must sum to
+	// exactly 10,000x a single fill with no accumulated drift.
+	single := exactMul(int64(price), int64(qty))
+	want := new(big.Int).Mul(big.NewInt(single), big.NewInt(10000))
+	if want.Cmp(big.NewInt(int64(total))) != 0 {
+		t.Errorf("accumulated total = %d, want %s (exact, no drift)", int64(total), want.String())
+	}
+}
+
+func TestDivMatchesDecimalQuotient(t *testing.T) {
+	got := NewFromInt(10).Div(NewFromInt(3)).Float64()
+	want := 10.0 / 3.0
+	if diff := math.Abs(got - want); diff > 1e-8 {
+		t.Errorf("10/3 = %v, want %v (diff %v)", got, want, diff)
+	}
+}
+
+func TestDivByZeroIsZero(t *testing.T) {
+	if got := NewFromInt(5).Div(Zero); got != Zero {
+		t.Errorf("Div by zero = %v, want Zero", got)
+	}
+}
@@ -0,0 +1,239 @@
+package trader
+
+import (
+	"fmt"
+
+	"nofx/fixedpoint"
+)
+
+// ArbLeg describes one hop of a multi-leg arbitrage path: opening (or adding
+// to) a position on Symbol/Side at Quantity and Leverage.
+type ArbLeg struct {
+	Symbol   string
+	Side     string // "long" or "short"
+	Quantity float64
+	Leverage int
+}
+
+// LegFill reports how one ArbLeg was (or would be) priced and filled.
+type LegFill struct {
+	Symbol   string
+	Side     string
+	Price    float64
+	Quantity float64
+	Notional float64
+	Fee      float64
+}
+
+// PathQuote is the projected outcome of executing a path of legs: every leg
+// priced against the current market, the fees it would incur, and the net
+// PnL of entering the path. Opening (or adding to) a position realizes no
+// PnL by itself, so NetPnL here is just -TotalFee, i.e. the guaranteed cost
+// of entering; callers compare it (or the per-leg notional) against their
+// own minSpreadRatio before deciding to call ExecutePath. NetPnL does not
+// include the PnL realized when ExecutePath nets a leg against an existing
+// opposite-side position.
+type PathQuote struct {
+	Legs     []LegFill
+	TotalFee float64
+	NetPnL   float64
+}
+
+// PathResult is the outcome of an executed path: the quote it filled at,
+// plus the order IDs assigned to each leg in order.
+type PathResult struct {
+	PathQuote
+	OrderIDs []int64
+}
+
+// pricedLeg is the fixedpoint-domain expansion of one ArbLeg, computed once
+// and shared between the precheck and fill passes so both price at the same
+// snapshot of the market.
+type pricedLeg struct {
+	symbol         string
+	side           string
+	leverage       int
+	price          fixedpoint.Value
+	quantity       fixedpoint.Value
+	notional       fixedpoint.Value
+	marginRequired fixedpoint.Value
+	fee            fixedpoint.Value
+}
+
+// priceLegs validates and prices every leg against marketPrice, returning
+// the priced legs and their combined margin+fee requirement. It takes no
+// lock and mutates nothing, so the caller may price legs before deciding
+// whether to hold st.mu for a real fill or just quote a dry run.
+func (st *SimulatedTrader) priceLegs(legs []ArbLeg) ([]pricedLeg, fixedpoint.Value, error) {
+	if len(legs) == 0 {
+		return nil, fixedpoint.Zero, fmt.Errorf("at least one leg is required")
+	}
+
+	priced := make([]pricedLeg, 0, len(legs))
+	total := fixedpoint.Zero
+
+	for _, leg := range legs {
+		if leg.Quantity <= 0 {
+			return nil, fixedpoint.Zero, fmt.Errorf("leg %s: quantity must be positive", leg.Symbol)
+		}
+		leverage := leg.Leverage
+		if leverage <= 0 {
+			leverage = 1
+		}
+		side := normalizeSide(leg.Side)
+		if side != "long" && side != "short" {
+			return nil, fixedpoint.Zero, fmt.Errorf("leg %s: invalid side %q", leg.Symbol, leg.Side)
+		}
+
+		price, err := priceOf(leg.Symbol)
+		if err != nil {
+			return nil, fixedpoint.Zero, fmt.Errorf("leg %s: %w", leg.Symbol, err)
+		}
+
+		quantity := fixedpoint.NewFromFloat(leg.Quantity)
+		notional := price.Mul(quantity)
+		marginRequired := notional.DivInt(int64(leverage))
+		fee := notional.Mul(st.takerFeeRate)
+
+		priced = append(priced, pricedLeg{
+			symbol:         leg.Symbol,
+			side:           side,
+			leverage:       leverage,
+			price:          price,
+			quantity:       quantity,
+			notional:       notional,
+			marginRequired: marginRequired,
+			fee:            fee,
+		})
+		total = total.Add(marginRequired).Add(fee)
+	}
+
+	return priced, total, nil
+}
+
+func quoteFromLegs(priced []pricedLeg) PathQuote {
+	quote := PathQuote{Legs: make([]LegFill, 0, len(priced))}
+	totalFee := fixedpoint.Zero
+	for _, p := range priced {
+		totalFee = totalFee.Add(p.fee)
+		quote.Legs = append(quote.Legs, LegFill{
+			Symbol:   p.symbol,
+			Side:     p.side,
+			Price:    p.price.Float64(),
+			Quantity: p.quantity.Float64(),
+			Notional: p.notional.Float64(),
+			Fee:      p.fee.Float64(),
+		})
+	}
+	quote.TotalFee = totalFee.Float64()
+	quote.NetPnL = -quote.TotalFee
+	return quote
+}
+
+// SimulateArbPath prices every leg against the current market without
+// mutating any balance or position, so strategies can gate on the resulting
+// PathQuote (e.g. against a minSpreadRatio) before calling ExecutePath.
+func (st *SimulatedTrader) SimulateArbPath(legs []ArbLeg) (PathQuote, error) {
+	priced, total, err := st.priceLegs(legs)
+	if err != nil {
+		return PathQuote{}, err
+	}
+
+	st.mu.Lock()
+	available := st.availableBalance
+	st.mu.Unlock()
+
+	if available.Compare(total) < 0 {
+		return PathQuote{}, fmt.Errorf("insufficient available balance for path: need %.4f, available %.4f", total.Float64(), available.Float64())
+	}
+
+	return quoteFromLegs(priced), nil
+}
+
+// pathSnapshot captures everything a leg of ExecutePath can mutate, so a
+// later leg's failure can be rolled back to leave no partial state
+// observable. This is necessary because a netting leg (openOrNet routing
+// into netPosition) has no bounded-loss check of its own — unlike the risk
+// engine's liquidate, which clamps realized loss to MarginUsed — so it can
+// consume far more balance than priceLegs' flat-open precheck reserved for
+// it, and a later leg can then fail after earlier legs already mutated state.
+type pathSnapshot struct {
+	walletBalance    fixedpoint.Value
+	availableBalance fixedpoint.Value
+	badDebt          fixedpoint.Value
+	orderCounter     int64
+	positions        map[string]*simulatedPosition
+	profitStats      map[string]*ProfitStats
+}
+
+// snapshotPath deep-copies the state ExecutePath's loop can mutate. Callers
+// must hold st.mu.
+func (st *SimulatedTrader) snapshotPath() pathSnapshot {
+	positions := make(map[string]*simulatedPosition, len(st.positions))
+	for k, p := range st.positions {
+		cp := *p
+		positions[k] = &cp
+	}
+	stats := make(map[string]*ProfitStats, len(st.profitStats))
+	for k, s := range st.profitStats {
+		cp := *s
+		stats[k] = &cp
+	}
+	return pathSnapshot{
+		walletBalance:    st.walletBalance,
+		availableBalance: st.availableBalance,
+		badDebt:          st.badDebt,
+		orderCounter:     st.orderCounter,
+		positions:        positions,
+		profitStats:      stats,
+	}
+}
+
+// restorePath undoes every leg applied since snap was taken. Callers must
+// hold st.mu.
+func (st *SimulatedTrader) restorePath(snap pathSnapshot) {
+	st.walletBalance = snap.walletBalance
+	st.availableBalance = snap.availableBalance
+	st.badDebt = snap.badDebt
+	st.orderCounter = snap.orderCounter
+	st.positions = snap.positions
+	st.profitStats = snap.profitStats
+}
+
+// ExecutePath prices every leg, checks the combined margin+fee requirement
+// against availableBalance, then fills all legs under a single st.mu hold
+// via openOrNet: either every leg fills or the path is rolled back to its
+// pre-call state, so no partial path is ever observable. Routing through
+// openOrNet means a leg that opposes an existing position on the same
+// symbol nets against it instead of opening a second, independent position.
+// priceLegs' combined check is only a conservative bound once netting is in
+// play — a netting leg can realize a loss far larger than a flat open would
+// have cost, so a later leg can still fail balance-wise after an earlier leg
+// already netted; snapshotPath/restorePath is what actually makes the path
+// atomic in that case.
+func (st *SimulatedTrader) ExecutePath(legs []ArbLeg) (PathResult, error) {
+	priced, total, err := st.priceLegs(legs)
+	if err != nil {
+		return PathResult{}, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.availableBalance.Compare(total) < 0 {
+		return PathResult{}, fmt.Errorf("insufficient available balance for path: need %.4f, available %.4f", total.Float64(), st.availableBalance.Float64())
+	}
+
+	snap := st.snapshotPath()
+	result := PathResult{PathQuote: quoteFromLegs(priced), OrderIDs: make([]int64, 0, len(priced))}
+
+	for _, p := range priced {
+		if err := st.openOrNet(p.symbol, p.side, p.quantity, p.price, st.takerFeeRate, p.leverage); err != nil {
+			st.restorePath(snap)
+			return PathResult{}, fmt.Errorf("leg %s: %w", p.symbol, err)
+		}
+		result.OrderIDs = append(result.OrderIDs, st.nextOrderID())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/fixedpoint"
+	"nofx/market"
+)
+
+// withPerSymbolPrice makes marketData return a different fixed price per
+// symbol until the test ends, for scenarios where legs of a path need to
+// price against distinct markets.
+func withPerSymbolPrice(t *testing.T, prices map[string]float64) {
+	t.Helper()
+	original := marketData
+	marketData = func(symbol string) (*market.Data, error) {
+		return &market.Data{CurrentPrice: prices[symbol]}, nil
+	}
+	t.Cleanup(func() { marketData = original })
+}
+
+func TestExecutePathFillsAllLegs(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	result, err := st.ExecutePath([]ArbLeg{
+		{Symbol: "SYM1", Side: "long", Quantity: 1, Leverage: 10},
+		{Symbol: "SYM2", Side: "short", Quantity: 1, Leverage: 10},
+	})
+	if err != nil {
+		t.Fatalf("ExecutePath: %v", err)
+	}
+	if len(result.OrderIDs) != 2 {
+		t.Errorf("OrderIDs = %v, want 2 entries", result.OrderIDs)
+	}
+	if _, exists := st.positions[st.positionKey("SYM1", "long")]; !exists {
+		t.Error("expected SYM1 long to be opened")
+	}
+	if _, exists := st.positions[st.positionKey("SYM2", "short")]; !exists {
+		t.Error("expected SYM2 short to be opened")
+	}
+}
+
+// TestExecutePathRollsBackEarlierLegsOnFailure reproduces the maintainer's
+// reported regression: leg 1 nets against (and fully closes) an existing
+// losing position, consuming far more balance than priceLegs' flat-open
+// precheck reserved for it; leg 2 then fails for insufficient balance. Every
+// effect of leg 1 must be undone, not just reported as an error.
+func TestExecutePathRollsBackEarlierLegsOnFailure(t *testing.T) {
+	withPerSymbolPrice(t, map[string]float64{"SYM1": 10, "SYM2": 100})
+	st := newZeroFeeTrader(2000)
+	defer close(st.done)
+
+	st.positions[st.positionKey("SYM1", "long")] = &simulatedPosition{
+		Symbol:      "SYM1",
+		Side:        "long",
+		Quantity:    fixedpoint.NewFromInt(10),
+		EntryPrice:  fixedpoint.NewFromInt(100),
+		Leverage:    2,
+		MarginUsed:  fixedpoint.NewFromInt(500),
+		Initialized: true,
+		CostBasis:   fixedpoint.NewFromInt(1000),
+	}
+	st.availableBalance = fixedpoint.NewFromInt(150)
+
+	_, err := st.ExecutePath([]ArbLeg{
+		{Symbol: "SYM1", Side: "short", Quantity: 10, Leverage: 2}, // nets, realizes -900 pnl
+		{Symbol: "SYM2", Side: "long", Quantity: 1, Leverage: 1},   // needs 100, now unaffordable
+	})
+	if err == nil {
+		t.Fatal("expected the path to fail on leg 2")
+	}
+
+	pos, exists := st.positions[st.positionKey("SYM1", "long")]
+	if !exists {
+		t.Fatal("expected leg 1's net-close to be rolled back, leaving the SYM1 long open")
+	}
+	if got := pos.Quantity.Float64(); got != 10 {
+		t.Errorf("SYM1 long quantity = %v, want 10 (unchanged)", got)
+	}
+	if got := st.walletBalance.Float64(); got != 2000 {
+		t.Errorf("walletBalance = %v, want 2000 (leg 1 rolled back)", got)
+	}
+	if got := st.availableBalance.Float64(); got != 150 {
+		t.Errorf("availableBalance = %v, want 150 (leg 1 rolled back)", got)
+	}
+	if _, exists := st.positions[st.positionKey("SYM2", "long")]; exists {
+		t.Error("expected SYM2 leg to have never been opened")
+	}
+}
@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/fixedpoint"
+)
+
+// newZeroFeeTrader builds a SimulatedTrader directly (bypassing
+// NewSimulatedTrader, so no background goroutines start) with both fee rates
+// at their zero value, isolating balance math from fee rounding.
+func newZeroFeeTrader(balance float64) *SimulatedTrader {
+	bal := fixedpoint.NewFromFloat(balance)
+	return &SimulatedTrader{
+		walletBalance:    bal,
+		availableBalance: bal,
+		positions:        make(map[string]*simulatedPosition),
+		orders:           make(map[int64]*LimitOrder),
+		orderBook:        make(map[string]map[int64]*LimitOrder),
+		pollInterval:     defaultPollInterval,
+		done:             make(chan struct{}),
+		events:           make(chan TradeEvent, tradeEventBufferSize),
+		profitStats:      make(map[string]*ProfitStats),
+	}
+}
+
+// TestZeroFeeOpenCloseRoundTrip is the property the decimal refactor exists
+// for: opening and fully closing a position at the same price, with no fees,
+// must return the wallet to its exact starting balance, not off by a
+// float64-rounding epsilon.
+func TestZeroFeeOpenCloseRoundTrip(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	if _, err := st.OpenLong("BTCUSDT", 1.23456789, 5); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := st.CloseLong("BTCUSDT", 1.23456789); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if got := st.walletBalance.Float64(); got != 10000 {
+		t.Errorf("walletBalance after round trip = %v, want 10000 exactly", got)
+	}
+	if got := st.availableBalance.Float64(); got != 10000 {
+		t.Errorf("availableBalance after round trip = %v, want 10000 exactly", got)
+	}
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected position to be fully closed")
+	}
+}
+
+// TestRepeatedDCAFillsNoDrift opens the same position many times at a price
+// exactly representable at the 1e8 scale and checks the VWAP entry price
+// lands exactly on it, with no accumulated rounding error across fills. A
+// float64 accumulator would drift off 100 after enough additions; fixed-point
+// addition is exact, so it must not.
+func TestRepeatedDCAFillsNoDrift(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(1e9)
+	defer close(st.done)
+
+	const fills = 500
+	for i := 0; i < fills; i++ {
+		if _, err := st.OpenLong("BTCUSDT", 0.01, 10); err != nil {
+			t.Fatalf("fill %d: %v", i, err)
+		}
+	}
+
+	pos := st.positions[st.positionKey("BTCUSDT", "long")]
+	if pos == nil {
+		t.Fatal("expected an open long position")
+	}
+
+	wantQty := fixedpoint.NewFromFloat(0.01 * fills)
+	if pos.Quantity != wantQty {
+		t.Errorf("quantity = %v, want %v", pos.Quantity, wantQty)
+	}
+	// Every fill is at the same price, so VWAP must equal that price exactly.
+	wantEntry := fixedpoint.NewFromFloat(100)
+	if pos.EntryPrice != wantEntry {
+		t.Errorf("entry price = %v, want %v (no drift across %d fills)", pos.EntryPrice, wantEntry, fills)
+	}
+}
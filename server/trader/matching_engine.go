@@ -0,0 +1,303 @@
+package trader
+
+import (
+	"fmt"
+	"time"
+
+	"nofx/fixedpoint"
+)
+
+// OrderType identifies the kind of resting order accepted by the matching engine.
+type OrderType string
+
+const (
+	OrderTypeLimit      OrderType = "LIMIT"
+	OrderTypeLimitMaker OrderType = "LIMIT_MAKER"
+	OrderTypeStopMarket OrderType = "STOP_MARKET"
+)
+
+// OrderStatus tracks the lifecycle of a resting order.
+type OrderStatus string
+
+const (
+	OrderStatusNew      OrderStatus = "NEW"
+	OrderStatusFilled   OrderStatus = "FILLED"
+	OrderStatusCanceled OrderStatus = "CANCELED"
+)
+
+// LimitOrder is a resting order parked in the simulated order book. Side mirrors
+// simulatedPosition.Side ("long" or "short") and describes the position the
+// order opens once filled.
+type LimitOrder struct {
+	OrderID       int64
+	Symbol        string
+	Side          string
+	Type          OrderType
+	Price         fixedpoint.Value // limit price ("LIMIT"/"LIMIT_MAKER") or trigger price ("STOP_MARKET")
+	Quantity      fixedpoint.Value
+	Leverage      int
+	Status        OrderStatus
+	ExecutedPrice fixedpoint.Value
+	ExecutedQty   fixedpoint.Value
+	FeePaid       fixedpoint.Value
+}
+
+func (st *SimulatedTrader) orderBookKey(symbol, side string, price fixedpoint.Value) string {
+	return fmt.Sprintf("%s_%s_%s", symbol, side, price)
+}
+
+// PlaceLimitOrder parks a LIMIT, LIMIT_MAKER, or STOP_MARKET order in the
+// per-symbol order book. LIMIT orders that already cross the current market
+// price fill immediately at the taker rate; LIMIT_MAKER orders that would
+// cross are rejected instead of converting to a taker fill, matching the
+// "post-only" semantics of the real exchanges this mirrors.
+func (st *SimulatedTrader) PlaceLimitOrder(symbol, side string, orderType OrderType, price, quantity float64, leverage int) (*LimitOrder, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("price must be positive")
+	}
+	if leverage <= 0 {
+		leverage = 1
+	}
+	side = normalizeSide(side)
+	if side != "long" && side != "short" {
+		return nil, fmt.Errorf("invalid side %q", side)
+	}
+
+	switch orderType {
+	case OrderTypeLimit, OrderTypeLimitMaker, OrderTypeStopMarket:
+	default:
+		return nil, fmt.Errorf("unsupported order type %q", orderType)
+	}
+
+	mark, err := priceOf(symbol)
+	if err != nil {
+		return nil, err
+	}
+	orderPrice := fixedpoint.NewFromFloat(price)
+	orderQty := fixedpoint.NewFromFloat(quantity)
+
+	st.mu.Lock()
+
+	order := &LimitOrder{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     orderType,
+		Price:    orderPrice,
+		Quantity: orderQty,
+		Leverage: leverage,
+		Status:   OrderStatusNew,
+	}
+
+	if orderType != OrderTypeStopMarket && crosses(side, orderPrice, mark) {
+		if orderType == OrderTypeLimitMaker {
+			st.mu.Unlock()
+			return nil, fmt.Errorf("limit maker order would cross the market at %.4f", mark.Float64())
+		}
+		order.OrderID = st.nextOrderID()
+		st.mu.Unlock()
+		if err := st.fillOrder(order, mark, st.takerFeeRate); err != nil {
+			return nil, err
+		}
+		return order, nil
+	}
+
+	order.OrderID = st.nextOrderID()
+	st.orders[order.OrderID] = order
+	key := st.orderBookKey(symbol, side, orderPrice)
+	if st.orderBook[key] == nil {
+		st.orderBook[key] = make(map[int64]*LimitOrder)
+	}
+	st.orderBook[key][order.OrderID] = order
+	st.mu.Unlock()
+
+	return order, nil
+}
+
+// CancelOrder removes a resting order from the book. Orders that already
+// filled or were canceled return an error.
+func (st *SimulatedTrader) CancelOrder(orderID int64) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	order, ok := st.orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %d not found", orderID)
+	}
+	if order.Status != OrderStatusNew {
+		return fmt.Errorf("order %d is not open (status=%s)", orderID, order.Status)
+	}
+
+	order.Status = OrderStatusCanceled
+	delete(st.orders, orderID)
+	key := st.orderBookKey(order.Symbol, order.Side, order.Price)
+	delete(st.orderBook[key], orderID)
+	if len(st.orderBook[key]) == 0 {
+		delete(st.orderBook, key)
+	}
+
+	return nil
+}
+
+// GetOpenOrders returns all resting orders for a symbol, or for every symbol
+// when symbol is empty.
+func (st *SimulatedTrader) GetOpenOrders(symbol string) []*LimitOrder {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	result := make([]*LimitOrder, 0)
+	for _, order := range st.orders {
+		if order.Status != OrderStatusNew {
+			continue
+		}
+		if symbol != "" && order.Symbol != symbol {
+			continue
+		}
+		cp := *order
+		result = append(result, &cp)
+	}
+	return result
+}
+
+// OnTick drives the matching engine with a fresh price for symbol. Call it
+// from a candle/tick feed for deterministic backtests; SimulatedTrader also
+// calls it itself from a background poll loop so live paper trading fills
+// resting orders without an external driver.
+func (st *SimulatedTrader) OnTick(symbol string, price float64) {
+	if price <= 0 {
+		return
+	}
+	mark := fixedpoint.NewFromFloat(price)
+
+	st.mu.Lock()
+	var toFill []*LimitOrder
+	for _, order := range st.orders {
+		if order.Status != OrderStatusNew || order.Symbol != symbol {
+			continue
+		}
+		if order.Type == OrderTypeStopMarket {
+			if stopTriggered(order.Side, order.Price, mark) {
+				toFill = append(toFill, order)
+			}
+			continue
+		}
+		if crosses(order.Side, order.Price, mark) {
+			toFill = append(toFill, order)
+		}
+	}
+	st.mu.Unlock()
+
+	for _, order := range toFill {
+		feeRate := st.makerFeeRate
+		fillPrice := order.Price
+		if order.Type == OrderTypeStopMarket {
+			feeRate = st.takerFeeRate
+			fillPrice = mark
+		}
+		_ = st.fillOrder(order, fillPrice, feeRate)
+	}
+}
+
+// fillOrder executes a resting (or immediately-crossing) order at fillPrice,
+// charging feeRate, and removes it from the book. Like every other entry
+// point that puts on a position, it routes through openOrNet so a fill that
+// crosses an existing opposite-side position nets against it instead of
+// opening a second, independent position on the same symbol.
+func (st *SimulatedTrader) fillOrder(order *LimitOrder, fillPrice, feeRate fixedpoint.Value) error {
+	notional := fillPrice.Mul(order.Quantity)
+	marginRequired := notional.DivInt(int64(order.Leverage))
+	fee := notional.Mul(feeRate)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	totalDeduction := marginRequired.Add(fee)
+	if st.availableBalance.Compare(totalDeduction) < 0 {
+		order.Status = OrderStatusCanceled
+		delete(st.orders, order.OrderID)
+		key := st.orderBookKey(order.Symbol, order.Side, order.Price)
+		delete(st.orderBook[key], order.OrderID)
+		if len(st.orderBook[key]) == 0 {
+			delete(st.orderBook, key)
+		}
+		return fmt.Errorf("insufficient available balance: need %.4f, available %.4f", totalDeduction.Float64(), st.availableBalance.Float64())
+	}
+
+	if err := st.openOrNet(order.Symbol, order.Side, order.Quantity, fillPrice, feeRate, order.Leverage); err != nil {
+		return err
+	}
+
+	order.Status = OrderStatusFilled
+	order.ExecutedPrice = fillPrice
+	order.ExecutedQty = order.Quantity
+	order.FeePaid = fee
+	delete(st.orders, order.OrderID)
+	bookKey := st.orderBookKey(order.Symbol, order.Side, order.Price)
+	delete(st.orderBook[bookKey], order.OrderID)
+	if len(st.orderBook[bookKey]) == 0 {
+		delete(st.orderBook, bookKey)
+	}
+
+	return nil
+}
+
+// crosses reports whether a resting limit order at orderPrice would execute
+// against a market tick at price: longs fill on dips to or below the limit,
+// shorts fill on rallies to or above it.
+func crosses(side string, orderPrice, price fixedpoint.Value) bool {
+	if side == "long" {
+		return price.Compare(orderPrice) <= 0
+	}
+	return price.Compare(orderPrice) >= 0
+}
+
+// stopTriggered reports whether a STOP_MARKET entry at stopPrice has been hit:
+// long stop entries trigger on breakouts above the trigger, short stop
+// entries on breakdowns below it.
+func stopTriggered(side string, stopPrice, price fixedpoint.Value) bool {
+	if side == "long" {
+		return price.Compare(stopPrice) >= 0
+	}
+	return price.Compare(stopPrice) <= 0
+}
+
+// pollLoop periodically re-prices every symbol with a resting order against
+// the live market feed so orders fill even without an external OnTick driver.
+func (st *SimulatedTrader) pollLoop() {
+	ticker := time.NewTicker(st.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-st.done:
+			return
+		case <-ticker.C:
+			for _, symbol := range st.openOrderSymbols() {
+				if price, err := marketPrice(symbol); err == nil {
+					st.OnTick(symbol, price)
+				}
+			}
+		}
+	}
+}
+
+func (st *SimulatedTrader) openOrderSymbols() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	seen := make(map[string]struct{})
+	symbols := make([]string, 0, len(st.orders))
+	for _, order := range st.orders {
+		if order.Status != OrderStatusNew {
+			continue
+		}
+		if _, ok := seen[order.Symbol]; ok {
+			continue
+		}
+		seen[order.Symbol] = struct{}{}
+		symbols = append(symbols, order.Symbol)
+	}
+	return symbols
+}
@@ -0,0 +1,180 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/fixedpoint"
+)
+
+func TestPlaceLimitOrderFillsImmediatelyWhenCrossing(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 100, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	if order.Status != OrderStatusFilled {
+		t.Errorf("status = %v, want FILLED (limit price already crossed by the 100 mark)", order.Status)
+	}
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; !exists {
+		t.Error("expected an open long position after the immediate fill")
+	}
+	if _, stillResting := st.orders[order.OrderID]; stillResting {
+		t.Error("expected the filled order to be removed from the book")
+	}
+}
+
+func TestPlaceLimitOrderRestsWhenNotCrossing(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 50, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	if order.Status != OrderStatusNew {
+		t.Errorf("status = %v, want NEW (limit price below the 100 mark)", order.Status)
+	}
+	if _, exists := st.orders[order.OrderID]; !exists {
+		t.Error("expected the order to be resting in the book")
+	}
+}
+
+func TestOnTickFillsRestingOrderAtLimitPrice(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 50, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	st.OnTick("BTCUSDT", 50)
+
+	if order.Status != OrderStatusFilled {
+		t.Errorf("status = %v, want FILLED once price ticks down to the limit", order.Status)
+	}
+	if got := order.ExecutedPrice.Float64(); got != 50 {
+		t.Errorf("executed price = %v, want 50 (the resting limit price, not the tick)", got)
+	}
+
+	pos := st.positions[st.positionKey("BTCUSDT", "long")]
+	if pos == nil {
+		t.Fatal("expected an open long position")
+	}
+	if got := pos.EntryPrice.Float64(); got != 50 {
+		t.Errorf("entry price = %v, want 50", got)
+	}
+}
+
+func TestCancelOrderRemovesFromBook(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 50, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	if err := st.CancelOrder(order.OrderID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if _, exists := st.orders[order.OrderID]; exists {
+		t.Error("expected the canceled order to be removed from st.orders")
+	}
+
+	st.OnTick("BTCUSDT", 50)
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected the canceled order to never fill")
+	}
+}
+
+func TestCancelOrderRejectsAlreadyFilled(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 100, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	if err := st.CancelOrder(order.OrderID); err == nil {
+		t.Error("expected canceling an already-filled order to fail")
+	}
+}
+
+// TestFillOrderNetsAgainstOppositePosition is the chunk0-1 regression case:
+// a crossing order on the opposite side of an existing position must net
+// against it instead of opening a second, independent position.
+func TestFillOrderNetsAgainstOppositePosition(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	if _, err := st.OpenLong("BTCUSDT", 1, 10); err != nil {
+		t.Fatalf("open long: %v", err)
+	}
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "short", OrderTypeLimit, 100, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	if order.Status != OrderStatusFilled {
+		t.Fatalf("status = %v, want FILLED", order.Status)
+	}
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected the long position to be fully netted away, not left open")
+	}
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "short")]; exists {
+		t.Error("expected no residual short: closing quantity exactly matched the long")
+	}
+}
+
+func TestFillOrderCancelsOnInsufficientBalance(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := newZeroFeeTrader(1)
+	defer close(st.done)
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 50, 1, 1)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	st.OnTick("BTCUSDT", 50)
+
+	if order.Status != OrderStatusCanceled {
+		t.Errorf("status = %v, want CANCELED (notional 50 needs far more margin than the 1 available)", order.Status)
+	}
+	if _, exists := st.orders[order.OrderID]; exists {
+		t.Error("expected the canceled order to be removed from st.orders")
+	}
+	if got := st.availableBalance.Float64(); got != 1 {
+		t.Errorf("availableBalance = %v, want 1 (unchanged by the failed fill)", got)
+	}
+}
+
+func TestFillOrderUsesMakerFeeForRestingOrder(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := NewSimulatedTrader(10000, false)
+	defer st.Close()
+
+	order, err := st.PlaceLimitOrder("BTCUSDT", "long", OrderTypeLimit, 50, 1, 10)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	st.OnTick("BTCUSDT", 50)
+
+	wantFee := fixedpoint.NewFromFloat(50).Mul(st.makerFeeRate)
+	if order.FeePaid != wantFee {
+		t.Errorf("fee paid = %v, want %v (maker rate for a resting fill)", order.FeePaid, wantFee)
+	}
+}
@@ -0,0 +1,223 @@
+package trader
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nofx/fixedpoint"
+)
+
+// persistenceSchemaVersion is bumped whenever PersistedState's shape changes
+// in a way that needs migration on Load.
+const persistenceSchemaVersion = 1
+
+const defaultPersistInterval = 2 * time.Second
+
+// ErrNoSnapshot is returned by Persistence.Load when no snapshot has been
+// saved yet; callers should treat it as "start from a fresh state".
+var ErrNoSnapshot = errors.New("trader: no persisted snapshot")
+
+// PersistedPosition is the on-disk/on-wire form of simulatedPosition.
+type PersistedPosition struct {
+	Symbol     string
+	Side       string
+	Quantity   float64
+	EntryPrice float64
+	Leverage   int
+	MarginUsed float64
+	StopLoss   float64
+	TakeProfit float64
+	MarginMode bool
+	CostBasis  float64
+}
+
+// PersistedState is the full snapshot of SimulatedTrader state that survives
+// a restart: balances, open positions, and the order ID counter.
+type PersistedState struct {
+	Version          int
+	WalletBalance    float64
+	AvailableBalance float64
+	BadDebt          float64
+	OrderCounter     int64
+	Positions        []PersistedPosition
+}
+
+// Persistence saves and restores SimulatedTrader state. Save is called from
+// a debounced background flusher, never on the hot path; Load is called
+// once at startup.
+type Persistence interface {
+	Save(state PersistedState) error
+	Load() (PersistedState, error)
+}
+
+// Option configures a SimulatedTrader at construction time.
+type Option func(*SimulatedTrader)
+
+// WithPersistence restores state from p on startup (if a snapshot exists)
+// and auto-persists to p in the background after every state-mutating call.
+func WithPersistence(p Persistence) Option {
+	return func(st *SimulatedTrader) {
+		st.persistence = p
+	}
+}
+
+// JSONFilePersistence stores a single JSON snapshot file under dir, writing
+// it atomically via a temp file + rename so a crash mid-write never leaves a
+// truncated snapshot behind.
+type JSONFilePersistence struct {
+	dir      string
+	filename string
+}
+
+// NewJSONFilePersistence returns a Persistence backend that keeps its
+// snapshot at dir/simulated_trader_state.json, creating dir if needed.
+func NewJSONFilePersistence(dir string) *JSONFilePersistence {
+	return &JSONFilePersistence{dir: dir, filename: "simulated_trader_state.json"}
+}
+
+func (p *JSONFilePersistence) path() string {
+	return filepath.Join(p.dir, p.filename)
+}
+
+func (p *JSONFilePersistence) Save(state PersistedState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(p.dir, ".simulated_trader_state-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, p.path())
+}
+
+func (p *JSONFilePersistence) Load() (PersistedState, error) {
+	data, err := os.ReadFile(p.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PersistedState{}, ErrNoSnapshot
+		}
+		return PersistedState{}, err
+	}
+
+	var state PersistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return PersistedState{}, err
+	}
+	return state, nil
+}
+
+// restoreState applies a loaded snapshot to st. Callers must hold no lock;
+// restoreState takes st.mu itself since it only runs once, before the
+// background poll/risk/persist loops start mutating concurrently.
+func (st *SimulatedTrader) restoreState(state PersistedState) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.walletBalance = fixedpoint.NewFromFloat(state.WalletBalance)
+	st.availableBalance = fixedpoint.NewFromFloat(state.AvailableBalance)
+	st.badDebt = fixedpoint.NewFromFloat(state.BadDebt)
+	st.orderCounter = state.OrderCounter
+
+	for _, p := range state.Positions {
+		st.positions[st.positionKey(p.Symbol, p.Side)] = &simulatedPosition{
+			Symbol:      p.Symbol,
+			Side:        p.Side,
+			Quantity:    fixedpoint.NewFromFloat(p.Quantity),
+			EntryPrice:  fixedpoint.NewFromFloat(p.EntryPrice),
+			Leverage:    p.Leverage,
+			MarginUsed:  fixedpoint.NewFromFloat(p.MarginUsed),
+			StopLoss:    fixedpoint.NewFromFloat(p.StopLoss),
+			TakeProfit:  fixedpoint.NewFromFloat(p.TakeProfit),
+			MarginMode:  p.MarginMode,
+			Initialized: true,
+			CostBasis:   fixedpoint.NewFromFloat(p.CostBasis),
+		}
+	}
+}
+
+// exportStateLocked snapshots the current state for persistence. Callers
+// must hold st.mu.
+func (st *SimulatedTrader) exportStateLocked() PersistedState {
+	positions := make([]PersistedPosition, 0, len(st.positions))
+	for _, pos := range st.positions {
+		positions = append(positions, PersistedPosition{
+			Symbol:     pos.Symbol,
+			Side:       pos.Side,
+			Quantity:   pos.Quantity.Float64(),
+			EntryPrice: pos.EntryPrice.Float64(),
+			Leverage:   pos.Leverage,
+			MarginUsed: pos.MarginUsed.Float64(),
+			StopLoss:   pos.StopLoss.Float64(),
+			TakeProfit: pos.TakeProfit.Float64(),
+			MarginMode: pos.MarginMode,
+			CostBasis:  pos.CostBasis.Float64(),
+		})
+	}
+
+	return PersistedState{
+		Version:          persistenceSchemaVersion,
+		WalletBalance:    st.walletBalance.Float64(),
+		AvailableBalance: st.availableBalance.Float64(),
+		BadDebt:          st.badDebt.Float64(),
+		OrderCounter:     st.orderCounter,
+		Positions:        positions,
+	}
+}
+
+// markDirty flags that state has changed since the last flush. Callers must
+// hold st.mu.
+func (st *SimulatedTrader) markDirty() {
+	if st.persistence != nil {
+		st.dirty = true
+	}
+}
+
+// persistLoop debounces writes to st.persistence: it flushes on a fixed
+// interval only when markDirty has fired since the last flush, and flushes
+// once more on shutdown so the final state isn't lost.
+func (st *SimulatedTrader) persistLoop() {
+	ticker := time.NewTicker(st.persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-st.done:
+			st.flush()
+			return
+		case <-ticker.C:
+			st.flush()
+		}
+	}
+}
+
+func (st *SimulatedTrader) flush() {
+	st.mu.Lock()
+	if !st.dirty {
+		st.mu.Unlock()
+		return
+	}
+	state := st.exportStateLocked()
+	st.dirty = false
+	st.mu.Unlock()
+
+	_ = st.persistence.Save(state)
+}
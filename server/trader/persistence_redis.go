@@ -0,0 +1,68 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPersistenceOptions configures RedisPersistence. KeyPrefix defaults to
+// "nofx:" when empty, so multiple traders can share one Redis instance by
+// giving each a distinct prefix.
+type RedisPersistenceOptions struct {
+	Addr      string
+	Password  string
+	DB        int
+	KeyPrefix string
+}
+
+// RedisPersistence stores a single snapshot key holding the JSON-encoded
+// PersistedState.
+type RedisPersistence struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisPersistence returns a Persistence backend that keeps its snapshot
+// at KeyPrefix+"simulated_trader:state" on the given Redis instance.
+func NewRedisPersistence(opts RedisPersistenceOptions) *RedisPersistence {
+	prefix := opts.KeyPrefix
+	if prefix == "" {
+		prefix = "nofx:"
+	}
+
+	return &RedisPersistence{
+		client: redis.NewClient(&redis.Options{
+			Addr:     opts.Addr,
+			Password: opts.Password,
+			DB:       opts.DB,
+		}),
+		key: prefix + "simulated_trader:state",
+	}
+}
+
+func (p *RedisPersistence) Save(state PersistedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(context.Background(), p.key, data, 0).Err()
+}
+
+func (p *RedisPersistence) Load() (PersistedState, error) {
+	data, err := p.client.Get(context.Background(), p.key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return PersistedState{}, ErrNoSnapshot
+		}
+		return PersistedState{}, err
+	}
+
+	var state PersistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return PersistedState{}, err
+	}
+	return state, nil
+}
@@ -0,0 +1,112 @@
+package trader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONFilePersistenceRoundTrip(t *testing.T) {
+	p := NewJSONFilePersistence(t.TempDir())
+
+	state := PersistedState{
+		Version:          persistenceSchemaVersion,
+		WalletBalance:    1234.5,
+		AvailableBalance: 1000,
+		BadDebt:          10,
+		OrderCounter:     7,
+		Positions: []PersistedPosition{
+			{Symbol: "BTCUSDT", Side: "long", Quantity: 1, EntryPrice: 100, Leverage: 10, MarginUsed: 10, CostBasis: 100},
+		},
+	}
+
+	if err := p.Save(state); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := p.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("loaded state = %+v, want %+v", loaded, state)
+	}
+}
+
+func TestJSONFilePersistenceLoadMissingReturnsErrNoSnapshot(t *testing.T) {
+	p := NewJSONFilePersistence(t.TempDir())
+
+	if _, err := p.Load(); err != ErrNoSnapshot {
+		t.Errorf("Load with no snapshot = %v, want ErrNoSnapshot", err)
+	}
+}
+
+// fakePersistence is an in-memory Persistence used to test SimulatedTrader's
+// restore-on-start and flush-on-dirty plumbing without touching disk.
+type fakePersistence struct {
+	saved   PersistedState
+	saveErr error
+	loadErr error
+}
+
+func (f *fakePersistence) Save(state PersistedState) error {
+	f.saved = state
+	return f.saveErr
+}
+
+func (f *fakePersistence) Load() (PersistedState, error) {
+	return f.saved, f.loadErr
+}
+
+func TestNewSimulatedTraderRestoresPersistedState(t *testing.T) {
+	p := &fakePersistence{saved: PersistedState{
+		Version:          persistenceSchemaVersion,
+		WalletBalance:    5000,
+		AvailableBalance: 4000,
+		BadDebt:          50,
+		OrderCounter:     3,
+		Positions: []PersistedPosition{
+			{Symbol: "BTCUSDT", Side: "long", Quantity: 2, EntryPrice: 100, Leverage: 5, MarginUsed: 40, CostBasis: 200},
+		},
+	}}
+
+	st := NewSimulatedTrader(1000, false, WithPersistence(p))
+	defer st.Close()
+
+	if got := st.WalletBalanceDecimal().Float64(); got != 5000 {
+		t.Errorf("walletBalance = %v, want 5000 (restored, not the 1000 ctor arg)", got)
+	}
+	if got := st.AvailableBalanceDecimal().Float64(); got != 4000 {
+		t.Errorf("availableBalance = %v, want 4000", got)
+	}
+	if got := st.BadDebtDecimal().Float64(); got != 50 {
+		t.Errorf("badDebt = %v, want 50", got)
+	}
+
+	pos := st.positions[st.positionKey("BTCUSDT", "long")]
+	if pos == nil {
+		t.Fatal("expected the restored BTCUSDT long position")
+	}
+	if got := pos.Quantity.Float64(); got != 2 {
+		t.Errorf("quantity = %v, want 2", got)
+	}
+}
+
+func TestFlushExportsCurrentStateWhenDirty(t *testing.T) {
+	p := &fakePersistence{loadErr: ErrNoSnapshot}
+	st := NewSimulatedTrader(1000, false, WithPersistence(p))
+	defer st.Close()
+
+	withFixedPrice(t, 100)
+	if _, err := st.OpenLong("BTCUSDT", 1, 10); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	st.flush()
+
+	if got := p.saved.WalletBalance; got == 0 {
+		t.Error("expected flush to export a non-zero wallet balance after opening a position")
+	}
+	if len(p.saved.Positions) != 1 {
+		t.Errorf("exported Positions = %v, want 1 entry", p.saved.Positions)
+	}
+}
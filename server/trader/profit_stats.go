@@ -0,0 +1,150 @@
+package trader
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"nofx/fixedpoint"
+)
+
+// aggregateStatsSymbol is the key under which lifetime totals across every
+// symbol are kept, separate from each symbol's own bucket.
+const aggregateStatsSymbol = "ALL"
+
+// ProfitStats tracks realized PnL, fees, and maker/taker/bid/ask volume for a
+// single symbol (or, under aggregateStatsSymbol, across all of them).
+// Accumulated* fields are lifetime totals; Today* fields reset at the UTC
+// day boundary.
+type ProfitStats struct {
+	Symbol string
+
+	AccumulatedPnL         float64
+	AccumulatedFee         float64
+	AccumulatedMakerVolume float64
+	AccumulatedTakerVolume float64
+	AccumulatedBidVolume   float64
+	AccumulatedAskVolume   float64
+
+	TodayPnL         float64
+	TodayFee         float64
+	TodayMakerVolume float64
+	TodayTakerVolume float64
+	TodayBidVolume   float64
+	TodayAskVolume   float64
+
+	dayYear int // UTC Year()*1000+YearDay() of the last roll check, used to roll Today* over
+}
+
+// rollIfNeeded resets the Today* counters when today is a different UTC day
+// than the last recorded fill.
+func (s *ProfitStats) rollIfNeeded(now time.Time) {
+	utc := now.UTC()
+	yearDay := utc.Year()*1000 + utc.YearDay()
+	if s.dayYear == yearDay {
+		return
+	}
+	s.dayYear = yearDay
+	s.TodayPnL = 0
+	s.TodayFee = 0
+	s.TodayMakerVolume = 0
+	s.TodayTakerVolume = 0
+	s.TodayBidVolume = 0
+	s.TodayAskVolume = 0
+}
+
+func (s *ProfitStats) record(notional, fee, pnl float64, isBid, isMaker bool) {
+	s.AccumulatedPnL += pnl
+	s.AccumulatedFee += fee
+	s.TodayPnL += pnl
+	s.TodayFee += fee
+
+	if isMaker {
+		s.AccumulatedMakerVolume += notional
+		s.TodayMakerVolume += notional
+	} else {
+		s.AccumulatedTakerVolume += notional
+		s.TodayTakerVolume += notional
+	}
+
+	if isBid {
+		s.AccumulatedBidVolume += notional
+		s.TodayBidVolume += notional
+	} else {
+		s.AccumulatedAskVolume += notional
+		s.TodayAskVolume += notional
+	}
+}
+
+// Report writes a short Slack-style summary of s to w.
+func (s ProfitStats) Report(w io.Writer) {
+	fmt.Fprintf(w, "*%s profit stats*\n", s.Symbol)
+	fmt.Fprintf(w, "> PnL: %.4f (today %.4f)\n", s.AccumulatedPnL, s.TodayPnL)
+	fmt.Fprintf(w, "> Fees: %.4f (today %.4f)\n", s.AccumulatedFee, s.TodayFee)
+	fmt.Fprintf(w, "> Maker/Taker volume: %.4f / %.4f (today %.4f / %.4f)\n",
+		s.AccumulatedMakerVolume, s.AccumulatedTakerVolume, s.TodayMakerVolume, s.TodayTakerVolume)
+	fmt.Fprintf(w, "> Bid/Ask volume: %.4f / %.4f (today %.4f / %.4f)\n",
+		s.AccumulatedBidVolume, s.AccumulatedAskVolume, s.TodayBidVolume, s.TodayAskVolume)
+}
+
+// recordFill updates per-symbol and aggregate profit stats for one fill.
+// isBid marks a buy-side fill (opening long or closing short); isMaker marks
+// a fill charged the maker fee rate. Callers must hold st.mu.
+func (st *SimulatedTrader) recordFill(symbol string, notional, fee, pnl fixedpoint.Value, isBid, isMaker bool) {
+	st.recordFillFloat(symbol, notional.Float64(), fee.Float64(), pnl.Float64(), isBid, isMaker)
+}
+
+// recordFillFloat is the float64-boundary half of recordFill: ProfitStats
+// stays in float64 since it is a reporting/aggregation surface, not part of
+// the balance-critical decimal math path.
+func (st *SimulatedTrader) recordFillFloat(symbol string, notional, fee, pnl float64, isBid, isMaker bool) {
+	now := time.Now()
+
+	stats, ok := st.profitStats[symbol]
+	if !ok {
+		stats = &ProfitStats{Symbol: symbol}
+		st.profitStats[symbol] = stats
+	}
+	stats.rollIfNeeded(now)
+	stats.record(notional, fee, pnl, isBid, isMaker)
+
+	total, ok := st.profitStats[aggregateStatsSymbol]
+	if !ok {
+		total = &ProfitStats{Symbol: aggregateStatsSymbol}
+		st.profitStats[aggregateStatsSymbol] = total
+	}
+	total.rollIfNeeded(now)
+	total.record(notional, fee, pnl, isBid, isMaker)
+}
+
+// GetProfitStats returns a snapshot of the stats for symbol, or the
+// aggregate across every symbol when symbol is empty.
+func (st *SimulatedTrader) GetProfitStats(symbol string) ProfitStats {
+	if symbol == "" {
+		symbol = aggregateStatsSymbol
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if stats, ok := st.profitStats[symbol]; ok {
+		stats.rollIfNeeded(time.Now())
+		return *stats
+	}
+	return ProfitStats{Symbol: symbol}
+}
+
+// GetAllProfitStats returns a snapshot of every per-symbol bucket, including
+// the aggregateStatsSymbol total.
+func (st *SimulatedTrader) GetAllProfitStats() map[string]ProfitStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[string]ProfitStats, len(st.profitStats))
+	for symbol, stats := range st.profitStats {
+		stats.rollIfNeeded(now)
+		result[symbol] = *stats
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordFillAccumulatesPerSymbolAndAggregate(t *testing.T) {
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	st.recordFillFloat("BTCUSDT", 1000, 0.4, 10, true, true)
+	st.recordFillFloat("BTCUSDT", 500, 0.25, -5, false, false)
+	st.recordFillFloat("ETHUSDT", 200, 0.1, 2, true, false)
+
+	btc := st.GetProfitStats("BTCUSDT")
+	if got := btc.AccumulatedPnL; got != 5 {
+		t.Errorf("BTCUSDT AccumulatedPnL = %v, want 5", got)
+	}
+	if got := btc.AccumulatedFee; got != 0.65 {
+		t.Errorf("BTCUSDT AccumulatedFee = %v, want 0.65", got)
+	}
+	if got := btc.AccumulatedMakerVolume; got != 1000 {
+		t.Errorf("BTCUSDT AccumulatedMakerVolume = %v, want 1000", got)
+	}
+	if got := btc.AccumulatedTakerVolume; got != 500 {
+		t.Errorf("BTCUSDT AccumulatedTakerVolume = %v, want 500", got)
+	}
+	if got := btc.AccumulatedBidVolume; got != 1000 {
+		t.Errorf("BTCUSDT AccumulatedBidVolume = %v, want 1000", got)
+	}
+	if got := btc.AccumulatedAskVolume; got != 500 {
+		t.Errorf("BTCUSDT AccumulatedAskVolume = %v, want 500", got)
+	}
+
+	total := st.GetProfitStats("")
+	if got := total.AccumulatedPnL; got != 7 {
+		t.Errorf("aggregate AccumulatedPnL = %v, want 7 (5 + 2 from ETHUSDT)", got)
+	}
+	if got := total.AccumulatedFee; got != 0.75 {
+		t.Errorf("aggregate AccumulatedFee = %v, want 0.75", got)
+	}
+}
+
+func TestGetAllProfitStatsIncludesAggregateBucket(t *testing.T) {
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	st.recordFillFloat("BTCUSDT", 1000, 0.4, 10, true, true)
+
+	all := st.GetAllProfitStats()
+	if _, ok := all["BTCUSDT"]; !ok {
+		t.Error("expected a BTCUSDT bucket")
+	}
+	if _, ok := all[aggregateStatsSymbol]; !ok {
+		t.Error("expected an aggregate bucket")
+	}
+}
+
+func TestProfitStatsRollIfNeededResetsTodayOnly(t *testing.T) {
+	s := &ProfitStats{Symbol: "BTCUSDT"}
+	day1 := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.rollIfNeeded(day1)
+	s.record(1000, 1, 5, true, true)
+
+	if got := s.TodayPnL; got != 5 {
+		t.Fatalf("TodayPnL = %v, want 5 before rolling", got)
+	}
+
+	day2 := time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)
+	s.rollIfNeeded(day2)
+
+	if got := s.TodayPnL; got != 0 {
+		t.Errorf("TodayPnL = %v, want 0 after rolling to a new day", got)
+	}
+	if got := s.AccumulatedPnL; got != 5 {
+		t.Errorf("AccumulatedPnL = %v, want 5 (lifetime total must survive the roll)", got)
+	}
+}
@@ -0,0 +1,231 @@
+package trader
+
+import (
+	"time"
+
+	"nofx/fixedpoint"
+)
+
+// tradeEventBufferSize bounds the Events() channel; a slow consumer drops
+// the oldest-pending event rather than blocking the risk monitor.
+const tradeEventBufferSize = 256
+
+// TradeEventReason distinguishes why a position was synthetically closed.
+type TradeEventReason string
+
+const (
+	ReasonStopLoss   TradeEventReason = "SL_HIT"
+	ReasonTakeProfit TradeEventReason = "TP_HIT"
+	ReasonLiquidated TradeEventReason = "LIQUIDATED"
+)
+
+// TradeEvent reports a fill generated by the risk monitor so strategies can
+// observe stop-loss, take-profit, and liquidation triggers without polling.
+type TradeEvent struct {
+	Symbol      string
+	Side        string
+	Reason      TradeEventReason
+	FillPrice   float64
+	Quantity    float64
+	Fee         float64
+	RealizedPnL float64
+	Time        time.Time
+}
+
+// Events returns a channel of fills generated by the risk monitor. Readers
+// must keep up; a full buffer drops the oldest event to stay non-blocking.
+func (st *SimulatedTrader) Events() <-chan TradeEvent {
+	return st.events
+}
+
+func (st *SimulatedTrader) emit(evt TradeEvent) {
+	select {
+	case st.events <- evt:
+	default:
+		select {
+		case <-st.events:
+		default:
+		}
+		select {
+		case st.events <- evt:
+		default:
+		}
+	}
+}
+
+// riskLoop periodically checks every open position's mark price against its
+// stop-loss, take-profit, and liquidation levels and synthesizes a close the
+// instant one is crossed.
+func (st *SimulatedTrader) riskLoop() {
+	ticker := time.NewTicker(st.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-st.done:
+			return
+		case <-ticker.C:
+			for _, symbol := range st.openPositionSymbols() {
+				if price, err := priceOf(symbol); err == nil {
+					st.checkRisk(symbol, price)
+				}
+			}
+		}
+	}
+}
+
+func (st *SimulatedTrader) openPositionSymbols() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	seen := make(map[string]struct{})
+	symbols := make([]string, 0, len(st.positions))
+	for _, pos := range st.positions {
+		if _, ok := seen[pos.Symbol]; ok {
+			continue
+		}
+		seen[pos.Symbol] = struct{}{}
+		symbols = append(symbols, pos.Symbol)
+	}
+	return symbols
+}
+
+// checkRisk evaluates every side of symbol against the latest mark price and
+// triggers at most one close per position per tick.
+func (st *SimulatedTrader) checkRisk(symbol string, price fixedpoint.Value) {
+	for _, side := range []string{"long", "short"} {
+		st.mu.Lock()
+		pos, exists := st.positions[st.positionKey(symbol, side)]
+		if !exists {
+			st.mu.Unlock()
+			continue
+		}
+		cp := *pos
+		st.mu.Unlock()
+
+		lev := float64(maxInt(cp.Leverage, 1))
+		liqPrice := calculateLiquidationPrice(cp.Side, cp.EntryPrice, lev)
+
+		switch {
+		case liqPrice.IsPositive() && liquidationTriggered(cp.Side, liqPrice, price):
+			st.liquidate(symbol, side, price)
+		case cp.StopLoss.IsPositive() && stopLossTriggered(cp.Side, cp.StopLoss, price):
+			st.triggerClose(symbol, side, price, ReasonStopLoss)
+		case cp.TakeProfit.IsPositive() && takeProfitTriggered(cp.Side, cp.TakeProfit, price):
+			st.triggerClose(symbol, side, price, ReasonTakeProfit)
+		}
+	}
+}
+
+// stopLossTriggered reports whether price has moved against a position far
+// enough to hit its stop: longs stop out on the way down, shorts on the way up.
+func stopLossTriggered(side string, stopPrice, price fixedpoint.Value) bool {
+	if side == "long" {
+		return price.Compare(stopPrice) <= 0
+	}
+	return price.Compare(stopPrice) >= 0
+}
+
+// takeProfitTriggered reports whether price has moved in favor of a position
+// far enough to hit its target: the mirror image of stopLossTriggered.
+func takeProfitTriggered(side string, takeProfitPrice, price fixedpoint.Value) bool {
+	if side == "long" {
+		return price.Compare(takeProfitPrice) >= 0
+	}
+	return price.Compare(takeProfitPrice) <= 0
+}
+
+// liquidationTriggered reports whether mark price has crossed a position's
+// liquidation price: longs liquidate on the way down, shorts on the way up.
+func liquidationTriggered(side string, liqPrice, price fixedpoint.Value) bool {
+	if side == "long" {
+		return price.Compare(liqPrice) <= 0
+	}
+	return price.Compare(liqPrice) >= 0
+}
+
+// triggerClose synthesizes a full close at price for reason (stop-loss or
+// take-profit) and emits the corresponding TradeEvent.
+func (st *SimulatedTrader) triggerClose(symbol, side string, price fixedpoint.Value, reason TradeEventReason) {
+	st.mu.Lock()
+	key := st.positionKey(symbol, side)
+	pos, exists := st.positions[key]
+	if !exists {
+		st.mu.Unlock()
+		return
+	}
+
+	qty := pos.Quantity
+	marginRelease := pos.MarginUsed
+	fee := price.Mul(qty).Mul(st.takerFeeRate)
+	pnl := unrealizedPnL(side, pos.EntryPrice, price, qty)
+
+	st.settleClose(marginRelease, pnl, fee)
+	st.recordFill(symbol, price.Mul(qty), fee, pnl, side == "short", false)
+	delete(st.positions, key)
+	st.markDirty()
+	st.mu.Unlock()
+
+	st.emit(TradeEvent{
+		Symbol:      symbol,
+		Side:        side,
+		Reason:      reason,
+		FillPrice:   price.Float64(),
+		Quantity:    qty.Float64(),
+		Fee:         fee.Float64(),
+		RealizedPnL: pnl.Float64(),
+		Time:        time.Now(),
+	})
+}
+
+// liquidate synthesizes a full close at price, the mark that crossed the
+// liquidation level (so a gap past that level fills at the mark), clamping the
+// realized loss to the position's margin and routing any remaining shortfall
+// into badDebt instead of letting walletBalance go negative.
+func (st *SimulatedTrader) liquidate(symbol, side string, price fixedpoint.Value) {
+	st.mu.Lock()
+	key := st.positionKey(symbol, side)
+	pos, exists := st.positions[key]
+	if !exists {
+		st.mu.Unlock()
+		return
+	}
+
+	qty := pos.Quantity
+	marginUsed := pos.MarginUsed
+	fee := price.Mul(qty).Mul(st.takerFeeRate)
+	pnl := unrealizedPnL(side, pos.EntryPrice, price, qty)
+	if pnl.Compare(marginUsed.Neg()) < 0 {
+		pnl = marginUsed.Neg()
+	}
+
+	st.settleClose(marginUsed, pnl, fee)
+	st.recordFill(symbol, price.Mul(qty), fee, pnl, side == "short", false)
+	delete(st.positions, key)
+	st.markDirty()
+	st.mu.Unlock()
+
+	st.emit(TradeEvent{
+		Symbol:      symbol,
+		Side:        side,
+		Reason:      ReasonLiquidated,
+		FillPrice:   price.Float64(),
+		Quantity:    qty.Float64(),
+		Fee:         fee.Float64(),
+		RealizedPnL: pnl.Float64(),
+		Time:        time.Now(),
+	})
+}
+
+// settleClose applies a close's balance impact, flooring walletBalance at
+// zero and recording any shortfall as badDebt. Callers must hold st.mu.
+func (st *SimulatedTrader) settleClose(marginRelease, pnl, fee fixedpoint.Value) {
+	st.availableBalance = st.availableBalance.Add(marginRelease).Add(pnl).Sub(fee)
+
+	newWallet := st.walletBalance.Add(pnl).Sub(fee)
+	if newWallet.IsNegative() {
+		st.badDebt = st.badDebt.Add(newWallet.Neg())
+		newWallet = fixedpoint.Zero
+	}
+	st.walletBalance = newWallet
+}
@@ -0,0 +1,146 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/fixedpoint"
+)
+
+func TestCheckRiskTriggersStopLoss(t *testing.T) {
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	st.positions[st.positionKey("BTCUSDT", "long")] = &simulatedPosition{
+		Symbol:      "BTCUSDT",
+		Side:        "long",
+		Quantity:    fixedpoint.NewFromInt(1),
+		EntryPrice:  fixedpoint.NewFromInt(100),
+		Leverage:    2, // liquidation price 50, well below the stop at 90
+		MarginUsed:  fixedpoint.NewFromInt(50),
+		StopLoss:    fixedpoint.NewFromInt(90),
+		Initialized: true,
+		CostBasis:   fixedpoint.NewFromInt(100),
+	}
+
+	st.checkRisk("BTCUSDT", fixedpoint.NewFromInt(90))
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected the stop-loss hit to close the position")
+	}
+
+	evt := <-st.events
+	if evt.Reason != ReasonStopLoss {
+		t.Errorf("event reason = %v, want %v", evt.Reason, ReasonStopLoss)
+	}
+	if got := evt.RealizedPnL; got != -10 {
+		t.Errorf("realized pnl = %v, want -10", got)
+	}
+}
+
+func TestCheckRiskTriggersTakeProfit(t *testing.T) {
+	st := newZeroFeeTrader(10000)
+	defer close(st.done)
+
+	st.positions[st.positionKey("BTCUSDT", "short")] = &simulatedPosition{
+		Symbol:      "BTCUSDT",
+		Side:        "short",
+		Quantity:    fixedpoint.NewFromInt(1),
+		EntryPrice:  fixedpoint.NewFromInt(100),
+		Leverage:    10,
+		MarginUsed:  fixedpoint.NewFromInt(10),
+		TakeProfit:  fixedpoint.NewFromInt(80),
+		Initialized: true,
+		CostBasis:   fixedpoint.NewFromInt(100),
+	}
+
+	st.checkRisk("BTCUSDT", fixedpoint.NewFromInt(80))
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "short")]; exists {
+		t.Error("expected the take-profit hit to close the position")
+	}
+
+	evt := <-st.events
+	if evt.Reason != ReasonTakeProfit {
+		t.Errorf("event reason = %v, want %v", evt.Reason, ReasonTakeProfit)
+	}
+	if got := evt.RealizedPnL; got != 20 {
+		t.Errorf("realized pnl = %v, want 20", got)
+	}
+}
+
+// TestLiquidateClampsLossAndRecordsBadDebt checks the invariant chunk0-2
+// exists for: a liquidation that would otherwise drive walletBalance
+// negative instead clamps the realized loss to the position's margin and
+// books the shortfall as badDebt.
+func TestLiquidateClampsLossAndRecordsBadDebt(t *testing.T) {
+	st := newZeroFeeTrader(5)
+	defer close(st.done)
+
+	st.positions[st.positionKey("BTCUSDT", "long")] = &simulatedPosition{
+		Symbol:      "BTCUSDT",
+		Side:        "long",
+		Quantity:    fixedpoint.NewFromInt(1),
+		EntryPrice:  fixedpoint.NewFromInt(100),
+		Leverage:    10,
+		MarginUsed:  fixedpoint.NewFromInt(10),
+		Initialized: true,
+		CostBasis:   fixedpoint.NewFromInt(100),
+	}
+	st.availableBalance = fixedpoint.NewFromInt(5)
+
+	// Liquidation price for a 10x long is entry*(1-1/10)=90; a gap-down to 10
+	// simulates slippage past it, so unclamped PnL would be (10-100)*1=-90 —
+	// clamped to -10 (MarginUsed). walletBalance (5) is still smaller than
+	// that clamped loss, so the floor/badDebt path must still engage.
+	st.checkRisk("BTCUSDT", fixedpoint.NewFromInt(10))
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected liquidation to close the position")
+	}
+	if got := st.walletBalance.Float64(); got != 0 {
+		t.Errorf("walletBalance = %v, want 0 (floored, not negative)", got)
+	}
+	if got := st.badDebt.Float64(); got != 5 {
+		t.Errorf("badDebt = %v, want 5 (the 10 clamped loss minus the 5 wallet balance)", got)
+	}
+
+	evt := <-st.events
+	if evt.Reason != ReasonLiquidated {
+		t.Errorf("event reason = %v, want %v", evt.Reason, ReasonLiquidated)
+	}
+	if got := evt.RealizedPnL; got != -10 {
+		t.Errorf("realized pnl = %v, want -10 (clamped to MarginUsed)", got)
+	}
+}
+
+// TestCloseLongLossFloorsWalletAndRecordsBadDebt checks that the floor/badDebt
+// accounting chunk0-2 introduced for the risk engine's own closes also holds
+// for a manual CloseLong that realizes a loss larger than walletBalance.
+func TestCloseLongLossFloorsWalletAndRecordsBadDebt(t *testing.T) {
+	withFixedPrice(t, 50)
+	st := newZeroFeeTrader(10)
+	defer close(st.done)
+
+	st.positions[st.positionKey("BTCUSDT", "long")] = &simulatedPosition{
+		Symbol:      "BTCUSDT",
+		Side:        "long",
+		Quantity:    fixedpoint.NewFromInt(1),
+		EntryPrice:  fixedpoint.NewFromInt(100),
+		Leverage:    10,
+		MarginUsed:  fixedpoint.NewFromInt(10),
+		Initialized: true,
+		CostBasis:   fixedpoint.NewFromInt(100),
+	}
+	st.availableBalance = fixedpoint.NewFromInt(10)
+
+	if _, err := st.CloseLong("BTCUSDT", 1); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if got := st.walletBalance.Float64(); got != 0 {
+		t.Errorf("walletBalance = %v, want 0 (floored, not negative)", got)
+	}
+	if got := st.badDebt.Float64(); got != 40 {
+		t.Errorf("badDebt = %v, want 40 (the 50-loss minus the 10 wallet balance)", got)
+	}
+}
@@ -2,51 +2,116 @@ package trader
 
 import (
 	"fmt"
-	"math"
 	"strings"
 	"sync"
+	"time"
 
+	"nofx/fixedpoint"
 	"nofx/market"
 )
 
-const defaultSimulatedFeeRate = 0.0004
+const (
+	defaultMakerFeeRate = 0.0002
+	defaultTakerFeeRate = 0.0004
+	defaultPollInterval = 500 * time.Millisecond
+)
 
 // simulatedPosition represents an in-memory position for paper trading.
+// Balances and prices are kept as fixedpoint.Value internally so repeated
+// fills don't accumulate float64 rounding drift; the public Trader interface
+// converts back to float64 only at its boundary.
 type simulatedPosition struct {
 	Symbol      string
 	Side        string // "long" or "short"
-	Quantity    float64
-	EntryPrice  float64
+	Quantity    fixedpoint.Value
+	EntryPrice  fixedpoint.Value
 	Leverage    int
-	MarginUsed  float64
-	StopLoss    float64
-	TakeProfit  float64
+	MarginUsed  fixedpoint.Value
+	StopLoss    fixedpoint.Value
+	TakeProfit  fixedpoint.Value
 	MarginMode  bool // true=cross, false=isolated (kept for compatibility logging)
 	Initialized bool
+
+	// CostBasis is the running sum of (fill price * fill quantity) across
+	// every additive open, i.e. a VWAP accumulator. EntryPrice is always kept
+	// equal to CostBasis/Quantity so avgOpenPrice stays accurate across DCA-in
+	// and partial-close scenarios without re-deriving it from history.
+	CostBasis fixedpoint.Value
 }
 
 // SimulatedTrader implements Trader interface without touching real exchanges.
 type SimulatedTrader struct {
 	mu sync.Mutex
 
-	walletBalance    float64
-	availableBalance float64
-	feeRate          float64
+	walletBalance    fixedpoint.Value
+	availableBalance fixedpoint.Value
+	makerFeeRate     fixedpoint.Value
+	takerFeeRate     fixedpoint.Value
 	isCrossMargin    bool
 
 	positions    map[string]*simulatedPosition // key = symbol + "_" + side
 	orderCounter int64
+
+	orders       map[int64]*LimitOrder            // all resting orders, keyed by orderID
+	orderBook    map[string]map[int64]*LimitOrder // key = symbol + "_" + side + "_" + price (orderBookKey), orderID set
+	pollInterval time.Duration
+	closeOnce    sync.Once
+	done         chan struct{}
+
+	badDebt fixedpoint.Value
+	events  chan TradeEvent
+
+	profitStats map[string]*ProfitStats // keyed by symbol, plus aggregateStatsSymbol
+
+	persistence     Persistence
+	dirty           bool
+	persistInterval time.Duration
 }
 
-// NewSimulatedTrader creates a paper-trading exchange adapter.
-func NewSimulatedTrader(initialBalance float64, isCrossMargin bool) *SimulatedTrader {
-	return &SimulatedTrader{
-		walletBalance:    initialBalance,
-		availableBalance: initialBalance,
-		feeRate:          defaultSimulatedFeeRate,
+// NewSimulatedTrader creates a paper-trading exchange adapter. Pass
+// WithPersistence to restore prior state on startup and auto-persist state
+// changes in the background.
+func NewSimulatedTrader(initialBalance float64, isCrossMargin bool, opts ...Option) *SimulatedTrader {
+	balance := fixedpoint.NewFromFloat(initialBalance)
+	st := &SimulatedTrader{
+		walletBalance:    balance,
+		availableBalance: balance,
+		makerFeeRate:     fixedpoint.NewFromFloat(defaultMakerFeeRate),
+		takerFeeRate:     fixedpoint.NewFromFloat(defaultTakerFeeRate),
 		isCrossMargin:    isCrossMargin,
 		positions:        make(map[string]*simulatedPosition),
+		orders:           make(map[int64]*LimitOrder),
+		orderBook:        make(map[string]map[int64]*LimitOrder),
+		pollInterval:     defaultPollInterval,
+		done:             make(chan struct{}),
+		events:           make(chan TradeEvent, tradeEventBufferSize),
+		profitStats:      make(map[string]*ProfitStats),
+		persistInterval:  defaultPersistInterval,
+	}
+
+	for _, opt := range opts {
+		opt(st)
 	}
+
+	if st.persistence != nil {
+		if state, err := st.persistence.Load(); err == nil {
+			st.restoreState(state)
+		}
+		go st.persistLoop()
+	}
+
+	go st.pollLoop()
+	go st.riskLoop()
+	return st
+}
+
+// Close stops the background price-poll and risk-monitor goroutines, and the
+// persist goroutine (after one final flush) when persistence is configured.
+// Safe to call more than once.
+func (st *SimulatedTrader) Close() {
+	st.closeOnce.Do(func() {
+		close(st.done)
+	})
 }
 
 func (st *SimulatedTrader) nextOrderID() int64 {
@@ -59,7 +124,7 @@ func (st *SimulatedTrader) positionKey(symbol, side string) string {
 }
 
 // clonePositions returns a shallow copy of current positions for read-only ops.
-func (st *SimulatedTrader) snapshot() ([]*simulatedPosition, float64, float64) {
+func (st *SimulatedTrader) snapshot() ([]*simulatedPosition, fixedpoint.Value, fixedpoint.Value) {
 	st.mu.Lock()
 	defer st.mu.Unlock()
 
@@ -71,27 +136,50 @@ func (st *SimulatedTrader) snapshot() ([]*simulatedPosition, float64, float64) {
 	return list, st.walletBalance, st.availableBalance
 }
 
+// WalletBalanceDecimal returns the exact wallet balance without the float64
+// conversion GetBalance performs for the public Trader interface.
+func (st *SimulatedTrader) WalletBalanceDecimal() fixedpoint.Value {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.walletBalance
+}
+
+// AvailableBalanceDecimal returns the exact available balance without the
+// float64 conversion GetBalance performs for the public Trader interface.
+func (st *SimulatedTrader) AvailableBalanceDecimal() fixedpoint.Value {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.availableBalance
+}
+
+// BadDebtDecimal returns the exact accumulated shortfall from liquidations
+// that would otherwise have driven walletBalance negative.
+func (st *SimulatedTrader) BadDebtDecimal() fixedpoint.Value {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.badDebt
+}
+
 // GetBalance returns simulated account balances.
 func (st *SimulatedTrader) GetBalance() (map[string]interface{}, error) {
 	positions, wallet, available := st.snapshot()
 
-	totalUnrealized := 0.0
+	totalUnrealized := fixedpoint.Zero
 	for _, pos := range positions {
-		price, err := marketPrice(pos.Symbol)
+		price, err := priceOf(pos.Symbol)
 		if err != nil {
 			continue
 		}
-		unrealized := unrealizedPnL(pos.Side, pos.EntryPrice, price, pos.Quantity)
-		totalUnrealized += unrealized
+		totalUnrealized = totalUnrealized.Add(unrealizedPnL(pos.Side, pos.EntryPrice, price, pos.Quantity))
 	}
 
 	return map[string]interface{}{
-		"totalWalletBalance":    wallet,
-		"wallet_balance":        wallet,
-		"balance":               wallet,
-		"availableBalance":      available,
-		"available_margin":      available,
-		"totalUnrealizedProfit": totalUnrealized,
+		"totalWalletBalance":    wallet.Float64(),
+		"wallet_balance":        wallet.Float64(),
+		"balance":               wallet.Float64(),
+		"availableBalance":      available.Float64(),
+		"available_margin":      available.Float64(),
+		"totalUnrealizedProfit": totalUnrealized.Float64(),
 	}, nil
 }
 
@@ -101,7 +189,7 @@ func (st *SimulatedTrader) GetPositions() ([]map[string]interface{}, error) {
 	result := make([]map[string]interface{}, 0, len(positions))
 
 	for _, pos := range positions {
-		price, err := marketPrice(pos.Symbol)
+		price, err := priceOf(pos.Symbol)
 		if err != nil {
 			continue
 		}
@@ -114,7 +202,7 @@ func (st *SimulatedTrader) GetPositions() ([]map[string]interface{}, error) {
 		liqPrice := calculateLiquidationPrice(pos.Side, entry, lev)
 		quantity := pos.Quantity
 		if pos.Side == "short" {
-			quantity = -quantity
+			quantity = quantity.Neg()
 		}
 
 		marginType := "isolated"
@@ -127,32 +215,37 @@ func (st *SimulatedTrader) GetPositions() ([]map[string]interface{}, error) {
 			crossWalletBalance = wallet
 		}
 
+		absQuantity := quantity
+		if absQuantity.IsNegative() {
+			absQuantity = absQuantity.Neg()
+		}
+
 		result = append(result, map[string]interface{}{
-			"symbol":            pos.Symbol,
-			"side":              pos.Side,
-			"positionSide":      strings.ToUpper(pos.Side),
-			"positionAmt":       quantity,
-			"entryPrice":        entry,
-			"leverage":          float64(pos.Leverage),
-			"markPrice":         price,
-			"unRealizedProfit":  unrealized,
-			"liquidationPrice":  liqPrice,
-			"marginType":        marginType,
-			"isolatedMargin":    marginUsed,
-			"notionalValue":     price * math.Abs(quantity),
-			"updateTime":        0,
-			"unrealizedProfit":  unrealized,
-			"positionMargin":    marginUsed,
-			"initialMargin":     marginUsed,
-			"maintMargin":       marginUsed / lev,
-			"marginRatio":       marginUsed / st.walletBalance,
-			"positionCost":      entry * math.Abs(quantity),
-			"stopLoss":          pos.StopLoss,
-			"takeProfit":        pos.TakeProfit,
-			"isolatedWallet":    marginUsed,
-			"maxNotionalValue":  0.0,
-			"availableBalance":  available,
-			"crossWalletBalance": crossWalletBalance,
+			"symbol":             pos.Symbol,
+			"side":               pos.Side,
+			"positionSide":       strings.ToUpper(pos.Side),
+			"positionAmt":        quantity.Float64(),
+			"entryPrice":         entry.Float64(),
+			"leverage":           float64(pos.Leverage),
+			"markPrice":          price.Float64(),
+			"unRealizedProfit":   unrealized.Float64(),
+			"liquidationPrice":   liqPrice.Float64(),
+			"marginType":         marginType,
+			"isolatedMargin":     marginUsed.Float64(),
+			"notionalValue":      price.Mul(absQuantity).Float64(),
+			"updateTime":         0,
+			"unrealizedProfit":   unrealized.Float64(),
+			"positionMargin":     marginUsed.Float64(),
+			"initialMargin":      marginUsed.Float64(),
+			"maintMargin":        marginUsed.Float64() / lev,
+			"marginRatio":        marginUsed.Float64() / wallet.Float64(),
+			"positionCost":       entry.Mul(absQuantity).Float64(),
+			"stopLoss":           pos.StopLoss.Float64(),
+			"takeProfit":         pos.TakeProfit.Float64(),
+			"isolatedWallet":     marginUsed.Float64(),
+			"maxNotionalValue":   0.0,
+			"availableBalance":   available.Float64(),
+			"crossWalletBalance": crossWalletBalance.Float64(),
 		})
 	}
 
@@ -169,6 +262,14 @@ func (st *SimulatedTrader) OpenShort(symbol string, quantity float64, leverage i
 	return st.openPosition(symbol, quantity, leverage, "short")
 }
 
+// openPosition opens or adds to a position at the current market price. A
+// same-side open merges additively into the existing position with a
+// quantity-weighted (VWAP) entry price and notional-weighted leverage,
+// matching how real exchanges treat repeated fills on one side rather than
+// rejecting the second order. An opposite-side open nets against the
+// existing position instead: it partially (or fully) closes the existing
+// side at the current price, then opens any residual quantity on the new
+// side.
 func (st *SimulatedTrader) openPosition(symbol string, quantity float64, leverage int, side string) (map[string]interface{}, error) {
 	if quantity <= 0 {
 		return nil, fmt.Errorf("quantity must be positive")
@@ -177,52 +278,131 @@ func (st *SimulatedTrader) openPosition(symbol string, quantity float64, leverag
 		leverage = 1
 	}
 
-	price, err := marketPrice(symbol)
+	price, err := priceOf(symbol)
 	if err != nil {
 		return nil, err
 	}
-
-	notional := price * quantity
-	marginRequired := notional / float64(leverage)
-	fee := notional * st.feeRate
-
-	key := st.positionKey(symbol, side)
+	qty := fixedpoint.NewFromFloat(quantity)
 
 	st.mu.Lock()
 	defer st.mu.Unlock()
 
-	if _, exists := st.positions[key]; exists {
-		return nil, fmt.Errorf("%s already has an open %s position", symbol, side)
+	if err := st.openOrNet(symbol, side, qty, price, st.takerFeeRate, leverage); err != nil {
+		return nil, err
+	}
+
+	orderID := st.nextOrderID()
+	return map[string]interface{}{
+		"orderId":  orderID,
+		"symbol":   symbol,
+		"status":   "FILLED",
+		"avgPrice": price.Float64(),
+	}, nil
+}
+
+// openOrNet is the shared entry point for every code path that puts on a
+// position at price — OpenLong/OpenShort, a matched LimitOrder, and each leg
+// of ExecutePath. An opposite-side position is netted first (partially or
+// fully closed, realizing PnL); any residual quantity then opens or adds to
+// side the same way a flat open would. feeRate lets callers charge the
+// maker or taker rate as appropriate. Callers must hold st.mu.
+func (st *SimulatedTrader) openOrNet(symbol, side string, quantity, price, feeRate fixedpoint.Value, leverage int) error {
+	if oppPos, exists := st.positions[st.positionKey(symbol, oppositeSide(side))]; exists {
+		return st.netPosition(symbol, oppPos, side, quantity, leverage, price, feeRate)
 	}
+	return st.openSameSide(symbol, side, quantity, price, feeRate, leverage)
+}
 
-	totalDeduction := marginRequired + fee
-	if st.availableBalance < totalDeduction {
-		return nil, fmt.Errorf("insufficient available balance: need %.4f, available %.4f", totalDeduction, st.availableBalance)
+// openSameSide opens a fresh position, or additively merges into an existing
+// same-side one with a quantity-weighted (VWAP) entry price and
+// notional-weighted leverage, matching how real exchanges treat repeated
+// fills on one side rather than rejecting the second order. Callers must
+// hold st.mu.
+func (st *SimulatedTrader) openSameSide(symbol, side string, quantity, price, feeRate fixedpoint.Value, leverage int) error {
+	notional := price.Mul(quantity)
+	marginRequired := notional.DivInt(int64(leverage))
+	fee := notional.Mul(feeRate)
+
+	totalDeduction := marginRequired.Add(fee)
+	if st.availableBalance.Compare(totalDeduction) < 0 {
+		return fmt.Errorf("insufficient available balance: need %.4f, available %.4f", totalDeduction.Float64(), st.availableBalance.Float64())
 	}
 
-	st.availableBalance -= totalDeduction
-	st.walletBalance -= fee
+	st.availableBalance = st.availableBalance.Sub(totalDeduction)
+	st.walletBalance = st.walletBalance.Sub(fee)
+	st.recordFill(symbol, notional, fee, fixedpoint.Zero, side == "long", feeRate.Compare(st.makerFeeRate) == 0)
 
-	st.positions[key] = &simulatedPosition{
-		Symbol:      symbol,
-		Side:        side,
-		Quantity:    quantity,
-		EntryPrice:  price,
-		Leverage:    leverage,
-		MarginUsed:  marginRequired,
-		MarginMode:  st.isCrossMargin,
-		Initialized: true,
+	key := st.positionKey(symbol, side)
+	if pos, exists := st.positions[key]; exists {
+		oldNotional := pos.EntryPrice.Mul(pos.Quantity)
+		pos.CostBasis = pos.CostBasis.Add(notional)
+		pos.Quantity = pos.Quantity.Add(quantity)
+		pos.EntryPrice = pos.CostBasis.Div(pos.Quantity)
+		pos.Leverage = weightedLeverage(oldNotional, pos.MarginUsed, notional, marginRequired)
+		pos.MarginUsed = pos.MarginUsed.Add(marginRequired)
+	} else {
+		st.positions[key] = &simulatedPosition{
+			Symbol:      symbol,
+			Side:        side,
+			Quantity:    quantity,
+			EntryPrice:  price,
+			Leverage:    leverage,
+			MarginUsed:  marginRequired,
+			MarginMode:  st.isCrossMargin,
+			Initialized: true,
+			CostBasis:   notional,
+		}
 	}
 
-	orderID := st.nextOrderID()
-	return map[string]interface{}{
-		"orderId": orderID,
-		"symbol":  symbol,
-		"status":  "FILLED",
-		"avgPrice": func() float64 {
-			return price
-		}(),
-	}, nil
+	st.markDirty()
+	return nil
+}
+
+// netPosition closes quantity against the existing opposite-side position at
+// price (up to its full size), realizing PnL, then opens any residual on
+// side via openSameSide. Callers must hold st.mu.
+func (st *SimulatedTrader) netPosition(symbol string, oppPos *simulatedPosition, side string, quantity fixedpoint.Value, leverage int, price, feeRate fixedpoint.Value) error {
+	opposite := oppositeSide(side)
+	closeQty := fixedpoint.Min(quantity, oppPos.Quantity)
+
+	proportion := closeQty.Div(oppPos.Quantity)
+	marginRelease := oppPos.MarginUsed.Mul(proportion)
+	closeFee := price.Mul(closeQty).Mul(feeRate)
+	pnl := unrealizedPnL(opposite, oppPos.EntryPrice, price, closeQty)
+
+	st.settleClose(marginRelease, pnl, closeFee)
+	st.recordFill(symbol, price.Mul(closeQty), closeFee, pnl, opposite == "short", feeRate.Compare(st.makerFeeRate) == 0)
+	st.markDirty()
+
+	oppKey := st.positionKey(symbol, opposite)
+	if closeQty.Compare(oppPos.Quantity) == 0 {
+		delete(st.positions, oppKey)
+	} else {
+		oppPos.Quantity = oppPos.Quantity.Sub(closeQty)
+		oppPos.MarginUsed = oppPos.MarginUsed.Sub(marginRelease)
+		oppPos.CostBasis = oppPos.EntryPrice.Mul(oppPos.Quantity)
+	}
+
+	residualQty := quantity.Sub(closeQty)
+	if !residualQty.IsPositive() {
+		return nil
+	}
+
+	return st.openSameSide(symbol, side, residualQty, price, feeRate, leverage)
+}
+
+// weightedLeverage recomputes a combined leverage as the notional-weighted
+// average of two fills, i.e. total notional over total margin.
+func weightedLeverage(notionalA, marginA, notionalB, marginB fixedpoint.Value) int {
+	totalMargin := marginA.Add(marginB)
+	if !totalMargin.IsPositive() {
+		return 1
+	}
+	lev := int(notionalA.Add(notionalB).Div(totalMargin).Float64())
+	if lev < 1 {
+		lev = 1
+	}
+	return lev
 }
 
 // CloseLong closes a simulated long position.
@@ -236,7 +416,7 @@ func (st *SimulatedTrader) CloseShort(symbol string, quantity float64) (map[stri
 }
 
 func (st *SimulatedTrader) closePosition(symbol string, quantity float64, side string) (map[string]interface{}, error) {
-	price, err := marketPrice(symbol)
+	price, err := priceOf(symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -252,43 +432,40 @@ func (st *SimulatedTrader) closePosition(symbol string, quantity float64, side s
 	}
 
 	closeQty := pos.Quantity
-	if quantity > 0 && quantity < pos.Quantity {
-		closeQty = quantity
+	requested := fixedpoint.NewFromFloat(quantity)
+	if requested.IsPositive() && requested.Compare(pos.Quantity) < 0 {
+		closeQty = requested
 	}
 
-	if closeQty <= 0 {
+	if !closeQty.IsPositive() {
 		return nil, fmt.Errorf("close quantity must be positive")
 	}
 
-	proportion := closeQty / pos.Quantity
-	marginRelease := pos.MarginUsed * proportion
-	fee := price * closeQty * st.feeRate
+	proportion := closeQty.Div(pos.Quantity)
+	marginRelease := pos.MarginUsed.Mul(proportion)
+	fee := price.Mul(closeQty).Mul(st.takerFeeRate)
 
-	var pnl float64
-	if side == "long" {
-		pnl = (price - pos.EntryPrice) * closeQty
-	} else {
-		pnl = (pos.EntryPrice - price) * closeQty
-	}
+	pnl := unrealizedPnL(side, pos.EntryPrice, price, closeQty)
 
-	st.availableBalance += marginRelease + pnl - fee
-	st.walletBalance += pnl - fee
+	st.settleClose(marginRelease, pnl, fee)
+	st.recordFill(symbol, price.Mul(closeQty), fee, pnl, side == "short", false)
 
-	if closeQty == pos.Quantity {
+	if closeQty.Compare(pos.Quantity) == 0 {
 		delete(st.positions, key)
 	} else {
-		pos.Quantity -= closeQty
-		pos.MarginUsed -= marginRelease
+		pos.Quantity = pos.Quantity.Sub(closeQty)
+		pos.MarginUsed = pos.MarginUsed.Sub(marginRelease)
+		pos.CostBasis = pos.EntryPrice.Mul(pos.Quantity)
 	}
+	st.markDirty()
 
 	orderID := st.nextOrderID()
+	st.markDirty()
 	return map[string]interface{}{
-		"orderId": orderID,
-		"symbol":  symbol,
-		"status":  "FILLED",
-		"avgPrice": func() float64 {
-			return price
-		}(),
+		"orderId":  orderID,
+		"symbol":   symbol,
+		"status":   "FILLED",
+		"avgPrice": price.Float64(),
 	}, nil
 }
 
@@ -304,6 +481,7 @@ func (st *SimulatedTrader) SetLeverage(symbol string, leverage int) error {
 	for _, side := range []string{"long", "short"} {
 		if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
 			pos.Leverage = leverage
+			st.markDirty()
 		}
 	}
 
@@ -321,6 +499,7 @@ func (st *SimulatedTrader) SetMarginMode(symbol string, isCrossMargin bool) erro
 			pos.MarginMode = isCrossMargin
 		}
 	}
+	st.markDirty()
 	return nil
 }
 
@@ -335,7 +514,8 @@ func (st *SimulatedTrader) SetStopLoss(symbol string, positionSide string, quant
 	defer st.mu.Unlock()
 	side := normalizeSide(positionSide)
 	if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
-		pos.StopLoss = stopPrice
+		pos.StopLoss = fixedpoint.NewFromFloat(stopPrice)
+		st.markDirty()
 	}
 	return nil
 }
@@ -346,7 +526,8 @@ func (st *SimulatedTrader) SetTakeProfit(symbol string, positionSide string, qua
 	defer st.mu.Unlock()
 	side := normalizeSide(positionSide)
 	if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
-		pos.TakeProfit = takeProfitPrice
+		pos.TakeProfit = fixedpoint.NewFromFloat(takeProfitPrice)
+		st.markDirty()
 	}
 	return nil
 }
@@ -357,7 +538,8 @@ func (st *SimulatedTrader) CancelStopLossOrders(symbol string) error {
 	defer st.mu.Unlock()
 	for _, side := range []string{"long", "short"} {
 		if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
-			pos.StopLoss = 0
+			pos.StopLoss = fixedpoint.Zero
+			st.markDirty()
 		}
 	}
 	return nil
@@ -369,7 +551,8 @@ func (st *SimulatedTrader) CancelTakeProfitOrders(symbol string) error {
 	defer st.mu.Unlock()
 	for _, side := range []string{"long", "short"} {
 		if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
-			pos.TakeProfit = 0
+			pos.TakeProfit = fixedpoint.Zero
+			st.markDirty()
 		}
 	}
 	return nil
@@ -387,8 +570,9 @@ func (st *SimulatedTrader) CancelStopOrders(symbol string) error {
 	defer st.mu.Unlock()
 	for _, side := range []string{"long", "short"} {
 		if pos, ok := st.positions[st.positionKey(symbol, side)]; ok {
-			pos.StopLoss = 0
-			pos.TakeProfit = 0
+			pos.StopLoss = fixedpoint.Zero
+			pos.TakeProfit = fixedpoint.Zero
+			st.markDirty()
 		}
 	}
 	return nil
@@ -401,8 +585,12 @@ func (st *SimulatedTrader) FormatQuantity(symbol string, quantity float64) (stri
 
 // Helpers
 
+// marketData is a seam over market.Get so tests can substitute a fixed price
+// feed instead of hitting the real market package.
+var marketData = market.Get
+
 func marketPrice(symbol string) (float64, error) {
-	data, err := market.Get(symbol)
+	data, err := marketData(symbol)
 	if err != nil {
 		return 0, err
 	}
@@ -412,31 +600,49 @@ func marketPrice(symbol string) (float64, error) {
 	return data.CurrentPrice, nil
 }
 
-func unrealizedPnL(side string, entry, mark, quantity float64) float64 {
+// priceOf wraps marketPrice and converts to fixedpoint.Value immediately, so
+// float64 precision loss only happens once, right at ingress, instead of
+// repeatedly throughout the internal math.
+func priceOf(symbol string) (fixedpoint.Value, error) {
+	price, err := marketPrice(symbol)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+	return fixedpoint.NewFromFloat(price), nil
+}
+
+func unrealizedPnL(side string, entry, mark, quantity fixedpoint.Value) fixedpoint.Value {
 	switch side {
 	case "long":
-		return (mark - entry) * quantity
+		return mark.Sub(entry).Mul(quantity)
 	case "short":
-		return (entry - mark) * quantity
+		return entry.Sub(mark).Mul(quantity)
 	default:
-		return 0
+		return fixedpoint.Zero
 	}
 }
 
-func calculateLiquidationPrice(side string, entry float64, leverage float64) float64 {
+func calculateLiquidationPrice(side string, entry fixedpoint.Value, leverage float64) fixedpoint.Value {
 	if leverage <= 0 {
-		return 0
+		return fixedpoint.Zero
 	}
 	switch side {
 	case "long":
-		return entry * (1 - 1/leverage)
+		return entry.Mul(fixedpoint.NewFromFloat(1 - 1/leverage))
 	case "short":
-		return entry * (1 + 1/leverage)
+		return entry.Mul(fixedpoint.NewFromFloat(1 + 1/leverage))
 	default:
-		return 0
+		return fixedpoint.Zero
 	}
 }
 
+func oppositeSide(side string) string {
+	if side == "long" {
+		return "short"
+	}
+	return "long"
+}
+
 func normalizeSide(positionSide string) string {
 	switch strings.ToUpper(positionSide) {
 	case "LONG":
@@ -454,4 +660,3 @@ func maxInt(a, b int) int {
 	}
 	return b
 }
-
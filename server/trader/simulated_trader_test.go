@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/market"
+)
+
+// withFixedPrice makes marketPrice return price for every symbol until the
+// test ends.
+func withFixedPrice(t *testing.T, price float64) {
+	t.Helper()
+	original := marketData
+	marketData = func(symbol string) (*market.Data, error) {
+		return &market.Data{CurrentPrice: price}, nil
+	}
+	t.Cleanup(func() { marketData = original })
+}
+
+func TestOpenPositionDCAIn(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := NewSimulatedTrader(10000, false)
+	defer st.Close()
+
+	if _, err := st.OpenLong("BTCUSDT", 1, 10); err != nil {
+		t.Fatalf("first open: %v", err)
+	}
+
+	withFixedPrice(t, 200)
+	if _, err := st.OpenLong("BTCUSDT", 1, 10); err != nil {
+		t.Fatalf("second open: %v", err)
+	}
+
+	pos := st.positions[st.positionKey("BTCUSDT", "long")]
+	if pos == nil {
+		t.Fatal("expected an open long position")
+	}
+	if got := pos.Quantity.Float64(); got != 2 {
+		t.Errorf("quantity = %v, want 2", got)
+	}
+	if got := pos.EntryPrice.Float64(); got != 150 {
+		t.Errorf("entry price = %v, want 150 (VWAP of 100 and 200)", got)
+	}
+}
+
+func TestClosePositionDCAOut(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := NewSimulatedTrader(10000, false)
+	defer st.Close()
+
+	if _, err := st.OpenLong("BTCUSDT", 2, 10); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := st.CloseLong("BTCUSDT", 1); err != nil {
+		t.Fatalf("partial close: %v", err)
+	}
+
+	pos := st.positions[st.positionKey("BTCUSDT", "long")]
+	if pos == nil {
+		t.Fatal("expected a remaining long position")
+	}
+	if got := pos.Quantity.Float64(); got != 1 {
+		t.Errorf("quantity = %v, want 1", got)
+	}
+	if got := pos.EntryPrice.Float64(); got != 100 {
+		t.Errorf("entry price = %v, want unchanged at 100", got)
+	}
+
+	if _, err := st.CloseLong("BTCUSDT", 1); err != nil {
+		t.Fatalf("final close: %v", err)
+	}
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected position to be fully closed")
+	}
+}
+
+func TestOpenPositionFlip(t *testing.T) {
+	withFixedPrice(t, 100)
+	st := NewSimulatedTrader(10000, false)
+	defer st.Close()
+
+	if _, err := st.OpenLong("BTCUSDT", 1, 10); err != nil {
+		t.Fatalf("open long: %v", err)
+	}
+
+	withFixedPrice(t, 150)
+	if _, err := st.OpenShort("BTCUSDT", 3, 10); err != nil {
+		t.Fatalf("flip to short: %v", err)
+	}
+
+	if _, exists := st.positions[st.positionKey("BTCUSDT", "long")]; exists {
+		t.Error("expected the long position to be fully netted away")
+	}
+
+	short := st.positions[st.positionKey("BTCUSDT", "short")]
+	if short == nil {
+		t.Fatal("expected a residual short position after flipping")
+	}
+	if got := short.Quantity.Float64(); got != 2 {
+		t.Errorf("short quantity = %v, want 2 (3 requested - 1 closed against the long)", got)
+	}
+	if got := short.EntryPrice.Float64(); got != 150 {
+		t.Errorf("short entry price = %v, want 150", got)
+	}
+}